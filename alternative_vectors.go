@@ -5,8 +5,8 @@ package main
 
 import (
 	"crypto/md5"
-	"encoding/binary"
 	"hash/fnv"
+	"math"
 )
 
 // SimpleHashVector creates a vector using FNV hash
@@ -169,5 +169,5 @@ func cosineSimilarity(a, b []float32) float32 {
 		return 0
 	}
 
-	return dotProduct / (float32(binary.Size(normA)) * float32(binary.Size(normB)))
+	return dotProduct / (float32(math.Sqrt(float64(normA))) * float32(math.Sqrt(float64(normB))))
 }
@@ -3,8 +3,6 @@ package main
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/weaviate/weaviate-go-client/v5/weaviate"
@@ -19,6 +17,7 @@ type VulnerabilityMetadata struct {
 	CVE              string
 	CVSSScore        float64
 	CVSSVector       string
+	References       []string
 	Function         string
 	FilePath         string
 	Library          string
@@ -29,114 +28,118 @@ type VulnerabilityMetadata struct {
 	FixedVersion     string
 	SourceRepo       string
 	CommitHash       string
+	ImageDigest      string
+	LayerDigest      string
+	Author           string
+	CommitDate       string
+	PreviousVersion  string
+	NextVersion      string
+	IdempotencyKey   string
 	AuditTool        string
 	Auditor          string
 }
 
 // IndexCodeFile reads a code file, generates its vector embedding, and stores it in Weaviate
 func IndexCodeFile(client *weaviate.Client, filePath string, schema string, metadata VulnerabilityMetadata) error {
-	// Read the code file
 	code, err := readCodeFromFile(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to read %s: %w", filePath, err)
 	}
 
+	if err := IndexCode(client, schema, code, metadata); err != nil {
+		return fmt.Errorf("failed to index %s: %w", filePath, err)
+	}
+
+	fmt.Printf("✓ Indexed: %s (%d bytes, %s)\n", filePath, len(code), metadata.VulnType)
+	return nil
+}
+
+// IndexCode generates a vector embedding for an already-in-memory code
+// snippet and stores it in Weaviate. This is the shared path behind
+// IndexCodeFile; it also backs callers that have no file on disk to read
+// from, such as the image/layer walker which streams tar entries straight
+// out of a registry blob.
+func IndexCode(client *weaviate.Client, schema string, code string, metadata VulnerabilityMetadata) error {
+	_, err := indexCodeObject(client, schema, code, metadata)
+	return err
+}
+
+// indexCodeObject is IndexCode's underlying implementation; it additionally
+// returns the new object's UUID so callers that need to cross-reference
+// objects after the fact (the git history indexer's previousVersion /
+// nextVersion links) don't have to re-query Weaviate for it.
+func indexCodeObject(client *weaviate.Client, schema string, code string, metadata VulnerabilityMetadata) (string, error) {
 	// Generate vector embedding
 	vector, err := getVectorFromPython(code)
 	if err != nil {
-		return fmt.Errorf("failed to generate vector for %s: %w", filePath, err)
+		return "", fmt.Errorf("failed to generate vector: %w", err)
 	}
 
 	// Index in Weaviate
-	_, err = client.Data().Creator().
+	result, err := client.Data().Creator().
 		WithClassName(schema).
-		WithProperties(map[string]interface{}{
-			"code":             code,
-			"language":         metadata.Language,
-			"vulnType":         metadata.VulnType,
-			"cwe":              metadata.CWE,
-			"cweDescription":   metadata.CWEDescription,
-			"cve":              metadata.CVE,
-			"cvssScore":        metadata.CVSSScore,
-			"cvssVector":       metadata.CVSSVector,
-			"function":         metadata.Function,
-			"filePath":         metadata.FilePath,
-			"library":          metadata.Library,
-			"severity":         metadata.Severity,
-			"exploitAvailable": metadata.ExploitAvailable,
-			"patchAvailable":   metadata.PatchAvailable,
-			"affectedVersion":  metadata.AffectedVersion,
-			"fixedVersion":     metadata.FixedVersion,
-			"sourceRepo":       metadata.SourceRepo,
-			"commitHash":       metadata.CommitHash,
-			"auditTool":        metadata.AuditTool,
-			"auditor":          metadata.Auditor,
-		}).
+		WithProperties(objectProperties(code, metadata)).
 		WithVector(vector).
 		Do(context.Background())
 
 	if err != nil {
-		return fmt.Errorf("failed to index %s: %w", filePath, err)
+		return "", fmt.Errorf("failed to index: %w", err)
 	}
 
-	fmt.Printf("✓ Indexed: %s (%d bytes, %s)\n", filePath, len(code), metadata.VulnType)
-	return nil
+	return string(result.Object.ID), nil
 }
 
-// IndexDirectory recursively indexes all code files in a directory
-func IndexDirectory(client *weaviate.Client, dirPath string, schema string) error {
-	var indexedCount, errorCount int
-
-	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Skip directories
-		if info.IsDir() {
-			return nil
-		}
-
-		// Detect language from extension
-		ext := strings.ToLower(filepath.Ext(path))
-		language := detectLanguage(ext)
-		if language == "" {
-			return nil // Skip non-code files
-		}
-
-		// Infer vulnerability type from filename (simple heuristic)
-		vulnType := inferVulnType(filepath.Base(path))
-
-		// Create metadata
-		metadata := VulnerabilityMetadata{
-			Language:         language,
-			VulnType:         vulnType,
-			CWE:              "CWE-Unknown",
-			CWEDescription:   "Automatically indexed code snippet",
-			Severity:         "Unknown",
-			FilePath:         path,
-			ExploitAvailable: false,
-			PatchAvailable:   false,
-			AuditTool:        "automated-indexer",
-			Auditor:          "weaver-system",
-		}
-
-		// Index the file
-		if err := IndexCodeFile(client, path, schema, metadata); err != nil {
-			fmt.Printf("✗ Error indexing %s: %v\n", path, err)
-			errorCount++
-			return nil // Continue processing other files
-		}
-
-		indexedCount++
-		return nil
-	})
+// objectProperties builds the Weaviate property map shared by single-object
+// indexing (Data().Creator()) and the batch pipeline (Batch().ObjectsBatcher()).
+func objectProperties(code string, metadata VulnerabilityMetadata) map[string]interface{} {
+	return map[string]interface{}{
+		"code":             code,
+		"language":         metadata.Language,
+		"vulnType":         metadata.VulnType,
+		"cwe":              metadata.CWE,
+		"cweDescription":   metadata.CWEDescription,
+		"cve":              metadata.CVE,
+		"cvssScore":        metadata.CVSSScore,
+		"cvssVector":       metadata.CVSSVector,
+		"references":       metadata.References,
+		"function":         metadata.Function,
+		"filePath":         metadata.FilePath,
+		"library":          metadata.Library,
+		"severity":         metadata.Severity,
+		"exploitAvailable": metadata.ExploitAvailable,
+		"patchAvailable":   metadata.PatchAvailable,
+		"affectedVersion":  metadata.AffectedVersion,
+		"fixedVersion":     metadata.FixedVersion,
+		"sourceRepo":       metadata.SourceRepo,
+		"commitHash":       metadata.CommitHash,
+		"imageDigest":      metadata.ImageDigest,
+		"layerDigest":      metadata.LayerDigest,
+		"author":           metadata.Author,
+		"commitDate":       metadata.CommitDate,
+		"previousVersion":  metadata.PreviousVersion,
+		"nextVersion":      metadata.NextVersion,
+		"idempotencyKey":   metadata.IdempotencyKey,
+		"auditTool":        metadata.AuditTool,
+		"auditor":          metadata.Auditor,
+	}
+}
 
+// setNextVersion patches the nextVersion cross-reference on a
+// previously-indexed object once a newer version of the same file has been
+// indexed, so a search hit on an old vulnerable snippet can point forward
+// to its fix.
+func setNextVersion(client *weaviate.Client, schema, objectID, nextVersionID string) error {
+	err := client.Data().Updater().
+		WithID(objectID).
+		WithClassName(schema).
+		WithMerge().
+		WithProperties(map[string]interface{}{
+			"nextVersion": nextVersionID,
+		}).
+		Do(context.Background())
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to link next version for %s: %w", objectID, err)
 	}
-
-	fmt.Printf("\nIndexing complete: %d files indexed, %d errors\n", indexedCount, errorCount)
 	return nil
 }
 
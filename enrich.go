@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/gaurav-gogia/weaver/metadata"
+	"github.com/gaurav-gogia/weaver/metadata/nvd"
+	"github.com/gaurav-gogia/weaver/metadata/osv"
+)
+
+// cveCommentRE matches a CVE ID appearing in a source comment, e.g.
+// "// CVE-2023-12345" or "# Fixes CVE-2021-44228".
+var cveCommentRE = regexp.MustCompile(`CVE-\d{4}-\d{4,}`)
+
+// ExtractCVE returns the first CVE ID found in code, if any.
+func ExtractCVE(code string) (string, bool) {
+	match := cveCommentRE.FindString(code)
+	return match, match != ""
+}
+
+// NewDefaultFetcher builds the NVD-then-OSV fallback chain used by the CLI:
+// NVD is authoritative for CVSS/CWE data, OSV fills in ecosystem advisories
+// (Go, PyPI, npm, ...) that NVD's feed doesn't describe well.
+func NewDefaultFetcher() (metadata.Fetcher, error) {
+	nvdFetcher, err := nvd.NewFetcher("")
+	if err != nil {
+		return nil, err
+	}
+	return metadata.Chain{nvdFetcher, osv.NewFetcher()}, nil
+}
+
+// EnrichMetadata looks up meta.CVE via fetcher and overlays the result onto
+// meta, leaving any field the caller already populated untouched so an
+// explicit --file invocation can still override individual fields.
+func EnrichMetadata(ctx context.Context, fetcher metadata.Fetcher, meta *VulnerabilityMetadata) error {
+	if meta.CVE == "" {
+		return nil
+	}
+
+	rec, err := fetcher.Fetch(ctx, meta.CVE)
+	if err != nil {
+		return err
+	}
+
+	if meta.CWE == "" {
+		meta.CWE = rec.CWE
+	}
+	if meta.CWEDescription == "" {
+		meta.CWEDescription = rec.CWEDescription
+	}
+	if meta.CVSSScore == 0 {
+		meta.CVSSScore = rec.CVSSScore
+	}
+	if meta.CVSSVector == "" {
+		meta.CVSSVector = rec.CVSSVector
+	}
+	if meta.Severity == "" || meta.Severity == "Unknown" {
+		meta.Severity = rec.Severity
+	}
+	if meta.AffectedVersion == "" {
+		meta.AffectedVersion = rec.AffectedVersion
+	}
+	if meta.FixedVersion == "" {
+		meta.FixedVersion = rec.FixedVersion
+	}
+	if len(meta.References) == 0 {
+		meta.References = rec.References
+	}
+
+	return nil
+}
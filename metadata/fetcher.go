@@ -0,0 +1,62 @@
+// Package metadata defines the pluggable lookup interface used to enrich a
+// VulnerabilityMetadata record from a bare CVE ID, and a fallback chain that
+// tries multiple sources in order.
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Record mirrors the subset of VulnerabilityMetadata that a fetcher is able
+// to populate from a CVE ID alone. main.VulnerabilityMetadata is built from
+// this plus whatever file-local fields (FilePath, SourceRepo, ...) the
+// indexer already knows.
+type Record struct {
+	CWE             string
+	CWEDescription  string
+	CVSSScore       float64
+	CVSSVector      string
+	Severity        string
+	AffectedVersion string
+	FixedVersion    string
+	References      []string
+}
+
+// Fetcher looks up enrichment data for a single CVE ID, e.g. "CVE-2023-12345".
+type Fetcher interface {
+	// Fetch returns the enrichment record for cve, or an error if the CVE is
+	// unknown to this source. Implementations should respect ctx cancellation.
+	Fetch(ctx context.Context, cve string) (*Record, error)
+}
+
+// ErrNotFound is returned by a Fetcher when the CVE is not present in its
+// data source, so that Chain knows to try the next one.
+var ErrNotFound = errors.New("metadata: cve not found")
+
+// Chain tries each Fetcher in order and returns the first successful
+// Record. This lets NVD (broad, authoritative) be tried first and OSV
+// (strong on Go/PyPI/npm ecosystem advisories) fill in the gaps.
+type Chain []Fetcher
+
+// Fetch satisfies Fetcher by delegating to each fetcher in the chain,
+// moving to the next one on ErrNotFound and returning immediately on any
+// other error or success.
+func (c Chain) Fetch(ctx context.Context, cve string) (*Record, error) {
+	var lastErr error
+	for _, f := range c {
+		rec, err := f.Fetch(ctx, cve)
+		if err == nil {
+			return rec, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = ErrNotFound
+	}
+	return nil, fmt.Errorf("metadata: %s not found in any source: %w", cve, lastErr)
+}
@@ -0,0 +1,396 @@
+// Package nvd fetches vulnerability metadata from the NVD JSON data feeds
+// (https://nvd.nist.gov/vuln/data-feeds), caching the yearly feeds under an
+// XDG data directory so repeated enrichment runs don't re-download several
+// hundred megabytes of JSON every time.
+package nvd
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gaurav-gogia/weaver/metadata"
+)
+
+const feedBaseURL = "https://nvd.nist.gov/feeds/json/cve/1.1"
+
+var cveYearRE = regexp.MustCompile(`^CVE-(\d{4})-\d+$`)
+
+// cveItem is the subset of the NVD 1.1 feed schema we care about.
+type cveItem struct {
+	CVE struct {
+		CVEDataMeta struct {
+			ID string `json:"ID"`
+		} `json:"CVE_data_meta"`
+		Problemtype struct {
+			ProblemtypeData []struct {
+				Description []struct {
+					Value string `json:"value"`
+				} `json:"description"`
+			} `json:"problemtype_data"`
+		} `json:"problemtype"`
+		References struct {
+			ReferenceData []struct {
+				URL string `json:"url"`
+			} `json:"reference_data"`
+		} `json:"references"`
+	} `json:"cve"`
+	Impact struct {
+		BaseMetricV3 struct {
+			CVSSV3 struct {
+				VectorString string  `json:"vectorString"`
+				BaseScore    float64 `json:"baseScore"`
+				BaseSeverity string  `json:"baseSeverity"`
+			} `json:"cvssV3"`
+		} `json:"baseMetricV3"`
+	} `json:"impact"`
+	Configurations struct {
+		Nodes []struct {
+			CPEMatch []struct {
+				VersionStartIncluding string `json:"versionStartIncluding"`
+				VersionEndExcluding   string `json:"versionEndExcluding"`
+			} `json:"cpe_match"`
+		} `json:"nodes"`
+	} `json:"configurations"`
+}
+
+type feedMeta struct {
+	LastModifiedDate string `json:"lastModifiedDate"`
+}
+
+// cweDescriptions gives a short, human-readable gloss for the CWE IDs the
+// NVD feed itself only ever reports as bare identifiers (e.g. "CWE-119").
+// Covers the common memory-safety/injection classes this tool is aimed at;
+// an ID with no entry here is left undescribed rather than guessed at.
+var cweDescriptions = map[string]string{
+	"CWE-22":  "Improper Limitation of a Pathname to a Restricted Directory (Path Traversal)",
+	"CWE-78":  "Improper Neutralization of Special Elements used in an OS Command (OS Command Injection)",
+	"CWE-79":  "Improper Neutralization of Input During Web Page Generation (Cross-site Scripting)",
+	"CWE-89":  "Improper Neutralization of Special Elements used in an SQL Command (SQL Injection)",
+	"CWE-119": "Improper Restriction of Operations within the Bounds of a Memory Buffer",
+	"CWE-120": "Buffer Copy without Checking Size of Input (Classic Buffer Overflow)",
+	"CWE-125": "Out-of-bounds Read",
+	"CWE-190": "Integer Overflow or Wraparound",
+	"CWE-287": "Improper Authentication",
+	"CWE-352": "Cross-Site Request Forgery (CSRF)",
+	"CWE-400": "Uncontrolled Resource Consumption",
+	"CWE-416": "Use After Free",
+	"CWE-434": "Unrestricted Upload of File with Dangerous Type",
+	"CWE-502": "Deserialization of Untrusted Data",
+	"CWE-787": "Out-of-bounds Write",
+	"CWE-798": "Use of Hard-coded Credentials",
+}
+
+// describeCWEs joins the known descriptions for a list of "CWE-NNN" IDs.
+// IDs without a known description are skipped rather than guessed at.
+func describeCWEs(cwes []string) string {
+	var descriptions []string
+	for _, cwe := range cwes {
+		if d, ok := cweDescriptions[cwe]; ok {
+			descriptions = append(descriptions, d)
+		}
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+// Fetcher implements metadata.Fetcher against the NVD JSON feeds. It keeps
+// a year's worth of CVEs in memory once loaded, and persists the
+// decompressed feed plus a last-modified cursor on disk so a process
+// restart doesn't force a re-download.
+type Fetcher struct {
+	httpClient *http.Client
+	cacheDir   string
+
+	mu    sync.Mutex
+	years map[int]map[string]*metadata.Record
+}
+
+// NewFetcher builds an NVD fetcher that caches downloaded feeds under
+// cacheDir. Pass "" to use the XDG-compliant default
+// (XDG_DATA_HOME/weaver/nvd, falling back to ~/.local/share/weaver/nvd).
+func NewFetcher(cacheDir string) (*Fetcher, error) {
+	if cacheDir == "" {
+		dir, err := defaultCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		cacheDir = dir
+	}
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return nil, fmt.Errorf("nvd: creating cache dir: %w", err)
+	}
+	return &Fetcher{
+		httpClient: &http.Client{Timeout: 2 * time.Minute},
+		cacheDir:   cacheDir,
+		years:      make(map[int]map[string]*metadata.Record),
+	}, nil
+}
+
+func defaultCacheDir() (string, error) {
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		return filepath.Join(xdg, "weaver", "nvd"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("nvd: resolving home dir: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", "weaver", "nvd"), nil
+}
+
+// Fetch returns the enrichment record for cve, loading (and if necessary
+// downloading) that CVE's feed year on first use.
+func (f *Fetcher) Fetch(ctx context.Context, cve string) (*metadata.Record, error) {
+	m := cveYearRE.FindStringSubmatch(strings.ToUpper(cve))
+	if m == nil {
+		return nil, fmt.Errorf("nvd: %q is not a CVE ID", cve)
+	}
+	year, _ := strconv.Atoi(m[1])
+
+	if err := f.ensureYear(ctx, year); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rec, ok := f.years[year][strings.ToUpper(cve)]
+	if !ok {
+		return nil, fmt.Errorf("nvd: %s: %w", cve, metadata.ErrNotFound)
+	}
+	return rec, nil
+}
+
+// Refresh re-checks every feed year currently loaded in memory against its
+// last-modified cursor, re-downloading any whose cached copy is stale or
+// missing and re-parsing each one. Used by the periodic sync goroutine and
+// by the `weaver enrich` CLI path.
+func (f *Fetcher) Refresh(ctx context.Context) error {
+	f.mu.Lock()
+	years := make([]int, 0, len(f.years))
+	for y := range f.years {
+		years = append(years, y)
+	}
+	f.mu.Unlock()
+
+	for _, year := range years {
+		if err := f.downloadYear(ctx, year); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartSync launches a goroutine that calls Refresh on the given interval
+// until ctx is cancelled. The caller is expected to have already loaded at
+// least one year via Fetch so there is something to refresh.
+func (f *Fetcher) StartSync(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = f.Refresh(ctx)
+			}
+		}
+	}()
+}
+
+// Prefetch loads each of years into the cache (downloading only if the
+// cached copy is stale or missing), so that a subsequent Refresh has
+// something to refresh. A fresh Fetcher's f.years starts out empty, so
+// callers like `weaver enrich` that want to force a refresh without having
+// Fetched any CVE yet must seed it via Prefetch first.
+func (f *Fetcher) Prefetch(ctx context.Context, years ...int) error {
+	for _, year := range years {
+		if err := f.ensureYear(ctx, year); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *Fetcher) ensureYear(ctx context.Context, year int) error {
+	f.mu.Lock()
+	_, loaded := f.years[year]
+	f.mu.Unlock()
+	if loaded {
+		return nil
+	}
+	return f.downloadYear(ctx, year)
+}
+
+// downloadYear checks the feed's last-modified cursor and, if the cached
+// copy is stale or missing, streams and decompresses the yearly feed before
+// parsing it into the in-memory index.
+func (f *Fetcher) downloadYear(ctx context.Context, year int) error {
+	remoteModified, err := f.fetchLastModified(ctx, year)
+	if err != nil {
+		return err
+	}
+
+	feedPath := filepath.Join(f.cacheDir, fmt.Sprintf("nvdcve-1.1-%d.json", year))
+	cursorPath := feedPath + ".cursor"
+
+	cached, _ := os.ReadFile(cursorPath)
+	if string(cached) != remoteModified || !fileExists(feedPath) {
+		if err := f.download(ctx, year, feedPath); err != nil {
+			return err
+		}
+		if err := os.WriteFile(cursorPath, []byte(remoteModified), 0o644); err != nil {
+			return fmt.Errorf("nvd: writing cursor for %d: %w", year, err)
+		}
+	}
+
+	index, err := parseFeed(feedPath)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.years[year] = index
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *Fetcher) fetchLastModified(ctx context.Context, year int) (string, error) {
+	url := fmt.Sprintf("%s/nvdcve-1.1-%d.meta", feedBaseURL, year)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("nvd: fetching meta for %d: %w", year, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var fm feedMeta
+	for _, line := range strings.Split(string(body), "\r\n") {
+		// The value itself is a timestamp with its own colons (e.g.
+		// "2024-01-05T03:00:12-05:00"), so split only the leading "key:" off
+		// and keep everything else intact rather than cutting on every colon.
+		if k, v, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(k) == "lastModifiedDate" {
+			fm.LastModifiedDate = strings.TrimSpace(v)
+		}
+	}
+	return fm.LastModifiedDate, nil
+}
+
+// download streams the compressed yearly feed straight into a decompressed
+// cache file: the HTTP body reader is wrapped in a gzip reader, so the full
+// archive is never held in memory or on disk in compressed form.
+func (f *Fetcher) download(ctx context.Context, year int, destPath string) error {
+	url := fmt.Sprintf("%s/nvdcve-1.1-%d.json.gz", feedBaseURL, year)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("nvd: downloading feed for %d: %w", year, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("nvd: feed for %d returned HTTP %d", year, resp.StatusCode)
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return fmt.Errorf("nvd: decompressing feed for %d: %w", year, err)
+	}
+	defer gz.Close()
+
+	tmp := destPath + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("nvd: creating cache file for %d: %w", year, err)
+	}
+	if _, err := io.Copy(out, gz); err != nil {
+		out.Close()
+		return fmt.Errorf("nvd: writing cache file for %d: %w", year, err)
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, destPath)
+}
+
+func parseFeed(path string) (map[string]*metadata.Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("nvd: opening cached feed %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var doc struct {
+		CVEItems []cveItem `json:"CVE_Items"`
+	}
+	if err := json.NewDecoder(f).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("nvd: parsing cached feed %s: %w", path, err)
+	}
+
+	index := make(map[string]*metadata.Record, len(doc.CVEItems))
+	for _, item := range doc.CVEItems {
+		index[item.CVE.CVEDataMeta.ID] = toRecord(item)
+	}
+	return index, nil
+}
+
+func toRecord(item cveItem) *metadata.Record {
+	rec := &metadata.Record{
+		CVSSScore:  item.Impact.BaseMetricV3.CVSSV3.BaseScore,
+		CVSSVector: item.Impact.BaseMetricV3.CVSSV3.VectorString,
+		Severity:   item.Impact.BaseMetricV3.CVSSV3.BaseSeverity,
+	}
+
+	var cwes []string
+	for _, pd := range item.CVE.Problemtype.ProblemtypeData {
+		for _, d := range pd.Description {
+			if d.Value != "" {
+				cwes = append(cwes, d.Value)
+			}
+		}
+	}
+	rec.CWE = strings.Join(cwes, ", ")
+	rec.CWEDescription = describeCWEs(cwes)
+
+	for _, ref := range item.CVE.References.ReferenceData {
+		rec.References = append(rec.References, ref.URL)
+	}
+
+	for _, node := range item.Configurations.Nodes {
+		for _, cpe := range node.CPEMatch {
+			if cpe.VersionStartIncluding != "" {
+				rec.AffectedVersion = ">=" + cpe.VersionStartIncluding
+			}
+			if cpe.VersionEndExcluding != "" {
+				rec.FixedVersion = cpe.VersionEndExcluding
+			}
+		}
+	}
+
+	return rec
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
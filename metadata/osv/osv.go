@@ -0,0 +1,194 @@
+// Package osv fetches vulnerability metadata from the OSV.dev API
+// (https://osv.dev), which has much better coverage of language-ecosystem
+// advisories (Go, PyPI, npm, ...) than NVD. It's meant to sit after nvd.Fetcher
+// in a metadata.Chain so ecosystem CVEs that NVD doesn't carry full CVSS/CWE
+// data for still get enriched.
+package osv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gaurav-gogia/weaver/metadata"
+)
+
+const queryURL = "https://api.osv.dev/v1/vulns/"
+
+// osvVuln is the subset of the OSV schema (https://ossf.github.io/osv-schema/)
+// we care about.
+type osvVuln struct {
+	Severity []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	DatabaseSpecific struct {
+		Severity string   `json:"severity"`
+		CWEIDs   []string `json:"cwe_ids"`
+	} `json:"database_specific"`
+	Affected []struct {
+		Ranges []struct {
+			Events []struct {
+				Introduced string `json:"introduced"`
+				Fixed      string `json:"fixed"`
+			} `json:"events"`
+		} `json:"ranges"`
+	} `json:"affected"`
+	References []struct {
+		URL string `json:"url"`
+	} `json:"references"`
+}
+
+// Fetcher implements metadata.Fetcher against the OSV.dev REST API. Unlike
+// nvd.Fetcher it has no local cache or periodic sync: OSV's API is cheap to
+// query on demand and a CVE ID maps to at most one OSV record.
+type Fetcher struct {
+	httpClient *http.Client
+}
+
+// NewFetcher builds an OSV fetcher using the default OSV.dev endpoint.
+func NewFetcher() *Fetcher {
+	return &Fetcher{httpClient: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Fetch looks up cve directly by its aliasing CVE ID; OSV indexes most
+// records under a GHSA/ecosystem ID but accepts CVE IDs as an alias lookup.
+func (f *Fetcher) Fetch(ctx context.Context, cve string) (*metadata.Record, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, queryURL+cve, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("osv: querying %s: %w", cve, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("osv: %s: %w", cve, metadata.ErrNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("osv: %s returned HTTP %d", cve, resp.StatusCode)
+	}
+
+	var vuln osvVuln
+	if err := json.NewDecoder(resp.Body).Decode(&vuln); err != nil {
+		return nil, fmt.Errorf("osv: decoding response for %s: %w", cve, err)
+	}
+
+	return toRecord(vuln), nil
+}
+
+func toRecord(v osvVuln) *metadata.Record {
+	rec := &metadata.Record{
+		CWE:      strings.Join(v.DatabaseSpecific.CWEIDs, ", "),
+		Severity: v.DatabaseSpecific.Severity,
+	}
+
+	for _, sev := range v.Severity {
+		if sev.Type == "CVSS_V3" {
+			rec.CVSSVector = sev.Score
+			if score, ok := cvssV3BaseScore(sev.Score); ok {
+				rec.CVSSScore = score
+			}
+		}
+	}
+
+	for _, affected := range v.Affected {
+		for _, r := range affected.Ranges {
+			for _, ev := range r.Events {
+				if ev.Introduced != "" {
+					rec.AffectedVersion = ">=" + ev.Introduced
+				}
+				if ev.Fixed != "" {
+					rec.FixedVersion = ev.Fixed
+				}
+			}
+		}
+	}
+
+	for _, ref := range v.References {
+		rec.References = append(rec.References, ref.URL)
+	}
+
+	return rec
+}
+
+// cvssV3AttackVector, etc. hold the base-metric weights from the CVSS v3.1
+// specification (https://www.first.org/cvss/v3-1/specification-document,
+// section 7.4), keyed by the vector string's single-letter metric value.
+var (
+	cvssV3AttackVector     = map[string]float64{"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2}
+	cvssV3AttackComplexity = map[string]float64{"L": 0.77, "H": 0.44}
+	cvssV3UserInteraction  = map[string]float64{"N": 0.85, "R": 0.62}
+	cvssV3Impact           = map[string]float64{"H": 0.56, "L": 0.22, "N": 0}
+	cvssV3PrivsUnchanged   = map[string]float64{"N": 0.85, "L": 0.62, "H": 0.27}
+	cvssV3PrivsChanged     = map[string]float64{"N": 0.85, "L": 0.68, "H": 0.5}
+)
+
+// cvssV3BaseScore computes the CVSS v3.x base score from a bare metric
+// vector (e.g. "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"), since OSV's
+// severity.score field for CVSS_V3 entries is the vector string itself, not
+// a precomputed number. Returns ok=false if the vector is missing a metric
+// this implementation needs.
+func cvssV3BaseScore(vector string) (score float64, ok bool) {
+	metrics := make(map[string]string)
+	for _, part := range strings.Split(vector, "/") {
+		if k, v, found := strings.Cut(part, ":"); found {
+			metrics[k] = v
+		}
+	}
+
+	scope := metrics["S"]
+	if scope != "U" && scope != "C" {
+		return 0, false
+	}
+	privs := cvssV3PrivsUnchanged
+	if scope == "C" {
+		privs = cvssV3PrivsChanged
+	}
+
+	av, avOK := cvssV3AttackVector[metrics["AV"]]
+	ac, acOK := cvssV3AttackComplexity[metrics["AC"]]
+	pr, prOK := privs[metrics["PR"]]
+	ui, uiOK := cvssV3UserInteraction[metrics["UI"]]
+	c, cOK := cvssV3Impact[metrics["C"]]
+	i, iOK := cvssV3Impact[metrics["I"]]
+	a, aOK := cvssV3Impact[metrics["A"]]
+	if !avOK || !acOK || !prOK || !uiOK || !cOK || !iOK || !aOK {
+		return 0, false
+	}
+
+	iscBase := 1 - (1-c)*(1-i)*(1-a)
+	var isc float64
+	if scope == "U" {
+		isc = 6.42 * iscBase
+	} else {
+		isc = 7.52*(iscBase-0.029) - 3.25*math.Pow(iscBase-0.02, 15)
+	}
+	if isc <= 0 {
+		return 0, true
+	}
+
+	exploitability := 8.22 * av * ac * pr * ui
+	if scope == "U" {
+		return cvssRoundUp(math.Min(isc+exploitability, 10)), true
+	}
+	return cvssRoundUp(math.Min(1.08*(isc+exploitability), 10)), true
+}
+
+// cvssRoundUp implements the CVSS spec's "Roundup" function: round to the
+// nearest 0.1, always rounding up, on the input scaled to an integer at
+// 1e-5 precision to sidestep float rounding error (mirrors the reference Roundup pseudocode).
+func cvssRoundUp(x float64) float64 {
+	intInput := math.Round(x * 100000)
+	if math.Mod(intInput, 10000) == 0 {
+		return intInput / 100000
+	}
+	return (math.Floor(intInput/10000) + 1) / 10
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/weaviate/weaviate-go-client/v5/weaviate"
+	"github.com/weaviate/weaviate-go-client/v5/weaviate/filters"
+	"github.com/weaviate/weaviate-go-client/v5/weaviate/graphql"
+	"github.com/weaviate/weaviate/entities/models"
+
+	"github.com/gaurav-gogia/weaver/metadata"
+)
+
+// BatchOptions configures the concurrent embedding/indexing pipeline used
+// by IndexDirectory.
+type BatchOptions struct {
+	Workers   int  // number of concurrent file-reading/idempotency-check workers
+	BatchSize int  // objects per Weaviate ObjectsBatcher() flush
+	JSON      bool // emit structured JSON-lines progress instead of human-readable output
+}
+
+// DefaultBatchOptions mirrors the CLI's defaults: a handful of workers and
+// a 100-object batch size, which is comfortably under Weaviate's default
+// batch request limits.
+func DefaultBatchOptions() BatchOptions {
+	return BatchOptions{Workers: 8, BatchSize: 100}
+}
+
+// embedBatchRequest is the request body for the Python sidecar's
+// /embed_batch endpoint.
+type embedBatchRequest struct {
+	Texts []string `json:"texts"`
+}
+
+// embedBatchResponse is the corresponding response: one vector per input text, in order.
+type embedBatchResponse struct {
+	Vectors [][]float64 `json:"vectors"`
+}
+
+// getVectorsFromPythonBatch embeds a batch of code snippets in a single
+// round trip to the sentence-transformer sidecar, instead of one HTTP call
+// per file.
+func getVectorsFromPythonBatch(codes []string) ([][]float32, error) {
+	jsonData, err := json.Marshal(embedBatchRequest{Texts: codes})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.Post("http://localhost:5005/embed_batch", "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result embedBatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if len(result.Vectors) != len(codes) {
+		return nil, fmt.Errorf("embed_batch returned %d vectors for %d inputs", len(result.Vectors), len(codes))
+	}
+
+	vectors := make([][]float32, len(result.Vectors))
+	for i, v := range result.Vectors {
+		vec := make([]float32, len(v))
+		for j, f := range v {
+			vec[j] = float32(f)
+		}
+		vectors[i] = vec
+	}
+	return vectors, nil
+}
+
+// idempotencyKey derives a stable key for (code, schema) so re-running an
+// indexing pass over an unchanged tree is a cheap no-op.
+func idempotencyKey(code, schema string) string {
+	sum := sha256.Sum256([]byte(schema + "\x00" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+// alreadyIndexed checks whether an object with the given idempotency key
+// already exists in schema, via a GraphQL where filter.
+func alreadyIndexed(client *weaviate.Client, schema, key string) (bool, error) {
+	where := filters.Where().
+		WithPath([]string{"idempotencyKey"}).
+		WithOperator(filters.Equal).
+		WithValueText(key)
+
+	result, err := client.GraphQL().Get().
+		WithClassName(schema).
+		WithFields(graphql.Field{Name: "idempotencyKey"}).
+		WithWhere(where).
+		WithLimit(1).
+		Do(context.Background())
+	if err != nil {
+		return false, fmt.Errorf("idempotency check failed: %w", err)
+	}
+
+	get, ok := result.Data["Get"].(map[string]interface{})
+	if !ok {
+		return false, nil
+	}
+	rows, ok := get[schema].([]interface{})
+	return ok && len(rows) > 0, nil
+}
+
+// progressEvent is one line of --json progress output.
+type progressEvent struct {
+	Event   string `json:"event"`
+	Path    string `json:"path,omitempty"`
+	Indexed int    `json:"indexed"`
+	Skipped int    `json:"skipped"`
+	Errors  int    `json:"errors"`
+}
+
+func emitProgress(jsonOutput bool, ev progressEvent) {
+	if jsonOutput {
+		line, err := json.Marshal(ev)
+		if err == nil {
+			fmt.Println(string(line))
+		}
+		return
+	}
+	switch ev.Event {
+	case "flush":
+		fmt.Printf("✓ Flushed batch (%d indexed, %d skipped, %d errors so far)\n", ev.Indexed, ev.Skipped, ev.Errors)
+	case "skip":
+		fmt.Printf("↷ Skipping unchanged: %s\n", ev.Path)
+	case "error":
+		fmt.Printf("✗ Error on %s\n", ev.Path)
+	}
+}
+
+// pendingObject is a file that has passed the idempotency check and is
+// waiting to be embedded and flushed as part of a batch.
+type pendingObject struct {
+	code string
+	meta VulnerabilityMetadata
+}
+
+// IndexDirectory walks dirPath with a producer goroutine, fans file reads
+// and idempotency checks out across opts.Workers workers, and assembles
+// the survivors into Weaviate ObjectsBatcher() requests of opts.BatchSize,
+// embedding each batch with a single call to /embed_batch. If fetcher is
+// non-nil, any file whose content contains a CVE ID is auto-enriched
+// before being queued.
+func IndexDirectory(client *weaviate.Client, dirPath string, schema string, fetcher metadata.Fetcher, opts BatchOptions) error {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultBatchOptions().Workers
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchOptions().BatchSize
+	}
+
+	paths := make(chan string, opts.Workers*2)
+	pending := make(chan pendingObject, opts.Workers*2)
+
+	var counts struct {
+		sync.Mutex
+		indexed, skipped, errors int
+	}
+
+	// Producer: walk the tree, emit candidate file paths.
+	var walkErr error
+	go func() {
+		defer close(paths)
+		walkErr = filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			if detectLanguage(strings.ToLower(filepath.Ext(path))) == "" {
+				return nil
+			}
+			paths <- path
+			return nil
+		})
+	}()
+
+	// Workers: read + enrich + idempotency-check each candidate file.
+	var workersWG sync.WaitGroup
+	workersWG.Add(opts.Workers)
+	for i := 0; i < opts.Workers; i++ {
+		go func() {
+			defer workersWG.Done()
+			for path := range paths {
+				code, err := readCodeFromFile(path)
+				if err != nil {
+					counts.Lock()
+					counts.errors++
+					counts.Unlock()
+					emitProgress(opts.JSON, progressEvent{Event: "error", Path: path})
+					continue
+				}
+
+				key := idempotencyKey(code, schema)
+				exists, err := alreadyIndexed(client, schema, key)
+				if err != nil {
+					counts.Lock()
+					counts.errors++
+					counts.Unlock()
+					emitProgress(opts.JSON, progressEvent{Event: "error", Path: path})
+					continue
+				}
+				if exists {
+					counts.Lock()
+					counts.skipped++
+					counts.Unlock()
+					emitProgress(opts.JSON, progressEvent{Event: "skip", Path: path})
+					continue
+				}
+
+				meta := VulnerabilityMetadata{
+					Language:       detectLanguage(strings.ToLower(filepath.Ext(path))),
+					VulnType:       inferVulnType(filepath.Base(path)),
+					CWE:            "CWE-Unknown",
+					CWEDescription: "Automatically indexed code snippet",
+					Severity:       "Unknown",
+					FilePath:       path,
+					IdempotencyKey: key,
+					AuditTool:      "automated-indexer",
+					Auditor:        "weaver-system",
+				}
+
+				if fetcher != nil {
+					if cve, ok := ExtractCVE(code); ok {
+						meta.CVE = cve
+						if err := EnrichMetadata(context.Background(), fetcher, &meta); err != nil {
+							fmt.Printf("⚠ Could not enrich %s from %s: %v\n", path, cve, err)
+						}
+					}
+				}
+
+				pending <- pendingObject{code: code, meta: meta}
+			}
+		}()
+	}
+
+	go func() {
+		workersWG.Wait()
+		close(pending)
+	}()
+
+	// Batcher: accumulate pending objects and flush in groups of BatchSize.
+	batch := make([]pendingObject, 0, opts.BatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := flushBatch(client, schema, batch); err != nil {
+			counts.Lock()
+			counts.errors += len(batch)
+			counts.Unlock()
+			batch = batch[:0]
+			return err
+		}
+		counts.Lock()
+		counts.indexed += len(batch)
+		indexed, skipped, errs := counts.indexed, counts.skipped, counts.errors
+		counts.Unlock()
+		emitProgress(opts.JSON, progressEvent{Event: "flush", Indexed: indexed, Skipped: skipped, Errors: errs})
+		batch = batch[:0]
+		return nil
+	}
+
+	for obj := range pending {
+		batch = append(batch, obj)
+		if len(batch) >= opts.BatchSize {
+			if err := flush(); err != nil {
+				fmt.Printf("✗ Batch flush failed: %v\n", err)
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		fmt.Printf("✗ Batch flush failed: %v\n", err)
+	}
+
+	if walkErr != nil {
+		return walkErr
+	}
+
+	fmt.Printf("\nIndexing complete: %d files indexed, %d skipped (already indexed), %d errors\n",
+		counts.indexed, counts.skipped, counts.errors)
+	return nil
+}
+
+// flushBatch embeds every snippet in objs with a single /embed_batch call,
+// then writes them all to Weaviate with ObjectsBatcher(), retrying with
+// exponential backoff on 429/503 responses.
+func flushBatch(client *weaviate.Client, schema string, objs []pendingObject) error {
+	codes := make([]string, len(objs))
+	for i, o := range objs {
+		codes[i] = o.code
+	}
+
+	vectors, err := getVectorsFromPythonBatch(codes)
+	if err != nil {
+		return fmt.Errorf("failed to embed batch: %w", err)
+	}
+
+	batchObjects := make([]*models.Object, len(objs))
+	for i, o := range objs {
+		batchObjects[i] = &models.Object{
+			Class:      schema,
+			Properties: objectProperties(o.code, o.meta),
+			Vector:     vectors[i],
+		}
+	}
+
+	return withBackoff(func() error {
+		resp, err := client.Batch().ObjectsBatcher().WithObjects(batchObjects...).Do(context.Background())
+		if err != nil {
+			return err
+		}
+		for _, r := range resp {
+			if r.Result != nil && r.Result.Errors != nil && len(r.Result.Errors.Error) > 0 {
+				return fmt.Errorf("weaviate rejected object %s: %s", r.ID, r.Result.Errors.Error[0].Message)
+			}
+		}
+		return nil
+	})
+}
+
+// withBackoff retries fn on transient (429/503-shaped) errors with
+// exponential backoff and jitter, up to 5 attempts.
+func withBackoff(fn func() error) error {
+	const maxAttempts = 5
+	delay := 500 * time.Millisecond
+
+	var err error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		err = fn()
+		if err == nil {
+			return nil
+		}
+		if !isRetryable(err) {
+			return err
+		}
+		jitter := time.Duration(rand.Int63n(int64(delay)))
+		time.Sleep(delay + jitter)
+		delay *= 2
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", maxAttempts, err)
+}
+
+func isRetryable(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "429") || strings.Contains(msg, "503") ||
+		strings.Contains(msg, "Too Many Requests") || strings.Contains(msg, "Service Unavailable")
+}
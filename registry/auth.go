@@ -0,0 +1,95 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Credentials holds optional basic-auth credentials for a registry. Leave
+// both fields empty for anonymous pulls (the default for public images on
+// Docker Hub, GCR, and GHCR).
+type Credentials struct {
+	Username string
+	Password string
+}
+
+var challengeParamRE = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// authenticate implements the distribution spec's token auth flow
+// (https://distribution.github.io/distribution/spec/auth/token/): probe
+// the registry's v2 endpoint, read the Www-Authenticate challenge it
+// returns, and exchange it (anonymously or with basic auth) for a bearer
+// token scoped to pulling repo.
+func (c *Client) authenticate(ctx context.Context, host, repo string, creds Credentials) (string, error) {
+	probeURL := fmt.Sprintf("https://%s/v2/", host)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probeURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("registry: probing %s: %w", host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return "", nil // registry doesn't require auth for this repo
+	}
+
+	challenge := resp.Header.Get("Www-Authenticate")
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", fmt.Errorf("registry: %s requires unsupported auth scheme %q", host, challenge)
+	}
+
+	params := map[string]string{}
+	for _, m := range challengeParamRE.FindAllStringSubmatch(challenge, -1) {
+		params[m[1]] = m[2]
+	}
+	realm, service := params["realm"], params["service"]
+	if realm == "" {
+		return "", fmt.Errorf("registry: %s auth challenge missing realm", host)
+	}
+
+	q := url.Values{}
+	if service != "" {
+		q.Set("service", service)
+	}
+	q.Set("scope", fmt.Sprintf("repository:%s:pull", repo))
+
+	tokenURL := realm + "?" + q.Encode()
+	tokenReq, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	if creds.Username != "" {
+		tokenReq.SetBasicAuth(creds.Username, creds.Password)
+	}
+
+	tokenResp, err := c.httpClient.Do(tokenReq)
+	if err != nil {
+		return "", fmt.Errorf("registry: fetching token from %s: %w", realm, err)
+	}
+	defer tokenResp.Body.Close()
+
+	if tokenResp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry: token endpoint %s returned HTTP %d", realm, tokenResp.StatusCode)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(tokenResp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("registry: decoding token response: %w", err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
@@ -0,0 +1,178 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	manifestListMediaTypes = "application/vnd.docker.distribution.manifest.list.v2+json, " +
+		"application/vnd.oci.image.index.v1+json, " +
+		"application/vnd.docker.distribution.manifest.v2+json, " +
+		"application/vnd.oci.image.manifest.v1+json"
+)
+
+// Layer is a single filesystem layer of a resolved image manifest.
+type Layer struct {
+	Digest    string
+	MediaType string
+}
+
+// Manifest is the subset of a resolved (single-platform) image manifest
+// Weaver needs to walk an image's layers.
+type Manifest struct {
+	Digest string // digest of this manifest itself, used as the indexed imageDigest
+	Layers []Layer
+}
+
+// platformManifest is a manifest-list/image-index entry.
+type platformManifest struct {
+	Digest   string `json:"digest"`
+	Platform struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+	} `json:"platform"`
+}
+
+type manifestDoc struct {
+	MediaType string             `json:"mediaType"`
+	Manifests []platformManifest `json:"manifests"` // present on manifest lists / image indexes
+	Layers    []struct {
+		MediaType string `json:"mediaType"`
+		Digest    string `json:"digest"`
+	} `json:"layers"` // present on single-platform manifests
+}
+
+// Client is a minimal OCI distribution (v2 registry API) client supporting
+// anonymous, basic-auth, and bearer-token pulls against Docker Hub, GCR,
+// GHCR, and any other spec-compliant registry.
+type Client struct {
+	httpClient *http.Client
+	creds      Credentials
+}
+
+// NewClient builds a registry client. Pass a zero Credentials for
+// anonymous pulls of public images.
+func NewClient(creds Credentials) *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: 5 * time.Minute},
+		creds:      creds,
+	}
+}
+
+// GetManifest resolves ref to a single-platform Manifest, following a
+// manifest list / OCI image index to the entry matching platform (e.g.
+// "linux/amd64") when the reference points at a multi-arch image.
+func (c *Client) GetManifest(ctx context.Context, ref Reference, platform string) (*Manifest, error) {
+	token, err := c.authenticate(ctx, ref.Registry, ref.Repository, c.creds)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, digest, err := c.fetchManifest(ctx, ref, ref.Tag, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(doc.Manifests) > 0 {
+		wantOS, wantArch, _ := strings.Cut(platform, "/")
+		if wantOS == "" {
+			wantOS, wantArch = "linux", "amd64"
+		}
+		var match *platformManifest
+		for i, m := range doc.Manifests {
+			if m.Platform.OS == wantOS && m.Platform.Architecture == wantArch {
+				match = &doc.Manifests[i]
+				break
+			}
+		}
+		if match == nil {
+			return nil, fmt.Errorf("registry: %s has no manifest for platform %s/%s", ref, wantOS, wantArch)
+		}
+		doc, digest, err = c.fetchManifest(ctx, ref, match.Digest, token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	m := &Manifest{Digest: digest}
+	for _, l := range doc.Layers {
+		m.Layers = append(m.Layers, Layer{Digest: l.Digest, MediaType: l.MediaType})
+	}
+	return m, nil
+}
+
+func (c *Client) fetchManifest(ctx context.Context, ref Reference, tagOrDigest, token string) (*manifestDoc, string, error) {
+	url := fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.Registry, ref.Repository, tagOrDigest)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", manifestListMediaTypes)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("registry: fetching manifest %s: %w", tagOrDigest, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("registry: manifest %s returned HTTP %d", tagOrDigest, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var doc manifestDoc
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, "", fmt.Errorf("registry: parsing manifest %s: %w", tagOrDigest, err)
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		sum := sha256.Sum256(body)
+		digest = fmt.Sprintf("sha256:%x", sum)
+	}
+	return &doc, digest, nil
+}
+
+// OpenBlob streams a layer blob by digest. The caller is responsible for
+// closing the returned reader. Callers should wrap this in a decompressing
+// reader (e.g. gzip) matching the layer's media type before reading tar
+// entries out of it, so the compressed blob is never buffered whole.
+func (c *Client) OpenBlob(ctx context.Context, ref Reference, digest string) (io.ReadCloser, error) {
+	token, err := c.authenticate(ctx, ref.Registry, ref.Repository, c.creds)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("https://%s/v2/%s/blobs/%s", ref.Registry, ref.Repository, digest)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("registry: fetching blob %s: %w", digest, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("registry: blob %s returned HTTP %d", digest, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,54 @@
+// Package registry is a minimal OCI distribution (v2 registry API) client:
+// enough to resolve a "registry/repo:tag" reference, authenticate against
+// Docker Hub, GCR, and GHCR, and stream manifests/blobs without pulling in a
+// full container runtime.
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Reference is a parsed "[registry/]repository[:tag]" image reference.
+type Reference struct {
+	Registry   string // e.g. "registry-1.docker.io", "ghcr.io"
+	Repository string // e.g. "library/alpine", "owner/app"
+	Tag        string // e.g. "latest", "v1.2.3"
+}
+
+// String renders the reference back into its canonical "registry/repo:tag" form.
+func (r Reference) String() string {
+	return fmt.Sprintf("%s/%s:%s", r.Registry, r.Repository, r.Tag)
+}
+
+// ParseReference parses a reference like "registry/foo:tag". Bare
+// references with no registry host (e.g. "alpine:3.19") default to Docker
+// Hub, and bare repositories with no namespace (e.g. "alpine") are
+// expanded to "library/alpine" to match Docker Hub's convention.
+func ParseReference(ref string) (Reference, error) {
+	if ref == "" {
+		return Reference{}, fmt.Errorf("registry: empty image reference")
+	}
+
+	name, tag := ref, "latest"
+	if i := strings.LastIndex(ref, ":"); i >= 0 && !strings.Contains(ref[i:], "/") {
+		name, tag = ref[:i], ref[i+1:]
+	}
+
+	parts := strings.SplitN(name, "/", 2)
+	host := "registry-1.docker.io"
+	repo := name
+	if len(parts) == 2 && (strings.Contains(parts[0], ".") || strings.Contains(parts[0], ":") || parts[0] == "localhost") {
+		host = parts[0]
+		repo = parts[1]
+	}
+
+	if host == "docker.io" {
+		host = "registry-1.docker.io"
+	}
+	if host == "registry-1.docker.io" && !strings.Contains(repo, "/") {
+		repo = "library/" + repo
+	}
+
+	return Reference{Registry: host, Repository: repo, Tag: tag}, nil
+}
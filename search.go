@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/weaviate/weaviate-go-client/v5/weaviate"
+	"github.com/weaviate/weaviate-go-client/v5/weaviate/filters"
+	"github.com/weaviate/weaviate-go-client/v5/weaviate/graphql"
+)
+
+// rerankCandidatePoolSize is how many hybrid hits are fetched before a
+// rerank pass narrows them back down to SearchOptions.Limit.
+const rerankCandidatePoolSize = 20
+
+// SearchOptions configures Search's hybrid query, filters, and reranking.
+type SearchOptions struct {
+	Alpha    float64 // hybrid tuning: 0 = pure BM25, 1 = pure vector
+	Rerank   bool    // POST the candidate pool to /rerank and reorder by it
+	Limit    int     // number of results to return
+	Language string  // exact-match filter
+	MinCVSS  float64 // minimum cvssScore, 0 disables the filter
+	CWE      string  // substring filter: matches results whose cwe field contains this value
+	Severity string  // exact-match filter
+}
+
+// Search runs a hybrid BM25 + vector search for code, combining a full-text
+// match over the code/function fields with sentence-transformer vector
+// similarity, then optionally reranks the candidate pool with a
+// cross-encoder before printing the top SearchOptions.Limit results.
+func Search(client *weaviate.Client, schema string, code string, opts SearchOptions) error {
+	vector, err := getVectorFromPython(code)
+	if err != nil {
+		return fmt.Errorf("failed to generate vector for query: %w", err)
+	}
+
+	limit := opts.Limit
+	fetchLimit := limit
+	if opts.Rerank && fetchLimit < rerankCandidatePoolSize {
+		fetchLimit = rerankCandidatePoolSize
+	}
+
+	hybrid := client.GraphQL().HybridArgBuilder().
+		WithQuery(code).
+		WithAlpha(float32(opts.Alpha)).
+		WithVector(vector).
+		WithProperties([]string{"code", "function"})
+
+	getBuilder := client.GraphQL().Get().
+		WithClassName(schema).
+		WithFields(
+			graphql.Field{Name: "code"},
+			graphql.Field{Name: "language"},
+			graphql.Field{Name: "vulnType"},
+			graphql.Field{Name: "cwe"},
+			graphql.Field{Name: "severity"},
+			graphql.Field{Name: "cvssScore"},
+			graphql.Field{Name: "filePath"},
+		).
+		WithHybrid(hybrid).
+		WithLimit(fetchLimit)
+
+	if where := buildSearchFilter(opts); where != nil {
+		getBuilder = getBuilder.WithWhere(where)
+	}
+
+	result, err := getBuilder.Do(context.Background())
+	if err != nil {
+		return fmt.Errorf("search failed: %w", err)
+	}
+
+	rows, err := extractRows(result, schema)
+	if err != nil {
+		return err
+	}
+
+	if opts.Rerank && len(rows) > 0 {
+		reranked, err := rerankRows(code, rows)
+		if err != nil {
+			fmt.Printf("⚠ Rerank failed, falling back to hybrid order: %v\n", err)
+		} else {
+			rows = reranked
+		}
+	}
+
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	printResults(rows)
+	return nil
+}
+
+// buildSearchFilter translates the --language/--min-cvss/--cwe/--severity
+// flags into a GraphQL where clause, ANDing together whichever are set.
+func buildSearchFilter(opts SearchOptions) *filters.WhereBuilder {
+	var operands []*filters.WhereBuilder
+
+	if opts.Language != "" {
+		operands = append(operands, filters.Where().
+			WithPath([]string{"language"}).
+			WithOperator(filters.Equal).
+			WithValueText(opts.Language))
+	}
+	if opts.CWE != "" {
+		operands = append(operands, filters.Where().
+			WithPath([]string{"cwe"}).
+			WithOperator(filters.Like).
+			WithValueText("*"+opts.CWE+"*"))
+	}
+	if opts.Severity != "" {
+		operands = append(operands, filters.Where().
+			WithPath([]string{"severity"}).
+			WithOperator(filters.Equal).
+			WithValueText(opts.Severity))
+	}
+	if opts.MinCVSS > 0 {
+		operands = append(operands, filters.Where().
+			WithPath([]string{"cvssScore"}).
+			WithOperator(filters.GreaterThanEqual).
+			WithValueNumber(opts.MinCVSS))
+	}
+
+	switch len(operands) {
+	case 0:
+		return nil
+	case 1:
+		return operands[0]
+	default:
+		return filters.Where().WithOperator(filters.And).WithOperands(operands)
+	}
+}
+
+// extractRows flattens a GraphQL Get response's Data["Get"][schema] array
+// into plain maps so callers don't need to repeat the type assertions.
+func extractRows(result *graphql.Result, schema string) ([]map[string]interface{}, error) {
+	get, ok := result.Data["Get"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	raw, ok := get[schema].([]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	rows := make([]map[string]interface{}, 0, len(raw))
+	for _, r := range raw {
+		row, ok := r.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected result row shape: %T", r)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// rerankRequest is the request body for the Python sidecar's /rerank
+// endpoint: a query plus the candidate texts to score against it.
+type rerankRequest struct {
+	Query      string   `json:"query"`
+	Candidates []string `json:"candidates"`
+}
+
+// rerankResponse holds one relevance score per candidate, in the same order.
+type rerankResponse struct {
+	Scores []float64 `json:"scores"`
+}
+
+// rerankRows scores rows' code fields against query with a cross-encoder
+// (e.g. ms-marco-MiniLM) and returns them reordered by descending score.
+func rerankRows(query string, rows []map[string]interface{}) ([]map[string]interface{}, error) {
+	candidates := make([]string, len(rows))
+	for i, row := range rows {
+		code, _ := row["code"].(string)
+		candidates[i] = code
+	}
+
+	jsonData, err := json.Marshal(rerankRequest{Query: query, Candidates: candidates})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.Post("http://localhost:5005/rerank", "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result rerankResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if len(result.Scores) != len(rows) {
+		return nil, fmt.Errorf("rerank returned %d scores for %d candidates", len(result.Scores), len(rows))
+	}
+
+	order := make([]int, len(rows))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return result.Scores[order[a]] > result.Scores[order[b]]
+	})
+
+	reranked := make([]map[string]interface{}, len(rows))
+	for i, idx := range order {
+		reranked[i] = rows[idx]
+	}
+	return reranked, nil
+}
+
+func printResults(rows []map[string]interface{}) {
+	if len(rows) == 0 {
+		fmt.Println("No results found.")
+		return
+	}
+
+	fmt.Printf("Results (%d):\n", len(rows))
+	for i, row := range rows {
+		fmt.Printf("%d. %v [%v] %v/%v - %v\n",
+			i+1, row["filePath"], row["language"], row["vulnType"], row["cwe"], row["severity"])
+	}
+}
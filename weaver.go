@@ -8,11 +8,18 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/weaviate/weaviate-go-client/v5/weaviate"
-	"github.com/weaviate/weaviate-go-client/v5/weaviate/graphql"
 	"github.com/weaviate/weaviate/entities/models"
+
+	"github.com/gaurav-gogia/weaver/metadata"
+	"github.com/gaurav-gogia/weaver/metadata/nvd"
+	"github.com/gaurav-gogia/weaver/registry"
 )
 
 // Request body for embedding service
@@ -72,7 +79,29 @@ func main() {
 	fileFlag := flag.String("file", "", "Index a single code file")
 	dirFlag := flag.String("dir", "examples", "Index all code files in a directory")
 	searchFlag := flag.String("search", "", "Search for similar vulnerabilities using a code file")
-	modeFlag := flag.String("mode", "dir", "Operation mode: 'file', 'dir', or 'search'")
+	modeFlag := flag.String("mode", "dir", "Operation mode: 'file', 'dir', 'search', 'image', 'git', or 'enrich'")
+	cveFlag := flag.String("cve", "", "CVE ID to auto-enrich metadata from (NVD, falling back to OSV)")
+	noEnrichFlag := flag.Bool("no-enrich", false, "Disable NVD/OSV auto-enrichment in 'dir' mode")
+	imageFlag := flag.String("image", "", "Container image reference to index, e.g. registry/foo:tag")
+	platformFlag := flag.String("platform", "linux/amd64", "Platform to select when the image reference is a multi-arch manifest list")
+	registryUserFlag := flag.String("username", "", "Username for authenticating to the registry in 'image' mode")
+	registryPassFlag := flag.String("password", "", "Password or access token for authenticating to the registry in 'image' mode")
+	gitFlag := flag.String("git", "", "Local path or clone URL of a git repository to index the history of")
+	sinceFlag := flag.String("since", "", "Only index commits logged from HEAD before this ref is reached")
+	rangeFlag := flag.String("range", "", "Only index commits in the \"<a>..<b>\" range")
+	onlyVulnFixesFlag := flag.Bool("only-vuln-fixes", false, "Only index commits whose message looks like a vulnerability fix")
+	workersFlag := flag.Int("workers", 8, "Number of concurrent workers in 'dir' mode's indexing pipeline")
+	batchSizeFlag := flag.Int("batch-size", 100, "Objects per Weaviate batch flush in 'dir' mode")
+	jsonFlag := flag.Bool("json", false, "Emit structured JSON-lines progress output, for driving from CI")
+	alphaFlag := flag.Float64("alpha", 0.5, "Hybrid search tuning for 'search' mode: 0 = pure BM25, 1 = pure vector")
+	rerankFlag := flag.Bool("rerank", false, "Rerank 'search' mode's hybrid results with the cross-encoder sidecar")
+	limitFlag := flag.Int("limit", 5, "Number of results to return in 'search' mode")
+	languageFlag := flag.String("language", "", "Filter 'search' mode results to this language")
+	cweFlag := flag.String("cwe", "", "Filter 'search' mode results to this CWE")
+	severityFlag := flag.String("severity", "", "Filter 'search' mode results to this severity")
+	minCVSSFlag := flag.Float64("min-cvss", 0, "Filter 'search' mode results to at least this CVSS score")
+	yearsFlag := flag.String("years", "", "Comma-separated NVD feed years to refresh in 'enrich' mode (default: current year)")
+	watchFlag := flag.Duration("watch", 0, "In 'enrich' mode, keep running and re-refresh NVD feeds on this interval instead of exiting (0 disables)")
 	flag.Parse()
 
 	fmt.Println("🚀 Weaver - Code Vulnerability Vector Database")
@@ -112,6 +141,7 @@ func main() {
 				{Name: "cve", DataType: []string{"text"}},            // e.g., CVE-2023-XXXX
 				{Name: "cvssScore", DataType: []string{"number"}},    // e.g., 7.8
 				{Name: "cvssVector", DataType: []string{"text"}},     // e.g., AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H
+				{Name: "references", DataType: []string{"text[]"}},   // advisory/fix URLs from the enrichment source
 
 				{Name: "function", DataType: []string{"text"}}, // function name
 				{Name: "filePath", DataType: []string{"text"}}, // location in source
@@ -123,10 +153,19 @@ func main() {
 				{Name: "affectedVersion", DataType: []string{"text"}},     // e.g., <1.2.3
 				{Name: "fixedVersion", DataType: []string{"text"}},        // e.g., >=1.2.4
 
-				{Name: "sourceRepo", DataType: []string{"text"}}, // GitHub/GitLab/etc.
-				{Name: "commitHash", DataType: []string{"text"}}, // reference commit
-				{Name: "auditTool", DataType: []string{"text"}},  // e.g., semgrep, snyk
-				{Name: "auditor", DataType: []string{"text"}},    // analyst or system name
+				{Name: "sourceRepo", DataType: []string{"text"}},  // GitHub/GitLab/etc.
+				{Name: "commitHash", DataType: []string{"text"}},  // reference commit
+				{Name: "imageDigest", DataType: []string{"text"}}, // digest of the source container image, when indexed via 'image' mode
+				{Name: "layerDigest", DataType: []string{"text"}}, // digest of the specific layer the snippet came from
+
+				{Name: "author", DataType: []string{"text"}},          // commit author, when indexed via 'git' mode
+				{Name: "commitDate", DataType: []string{"text"}},      // RFC3339 commit timestamp, when indexed via 'git' mode
+				{Name: "previousVersion", DataType: []string{"text"}}, // object ID of the prior indexed version of this file
+				{Name: "nextVersion", DataType: []string{"text"}},     // object ID of the next indexed version of this file
+				{Name: "idempotencyKey", DataType: []string{"text"}},  // sha256 of schema and code, used to skip re-indexing unchanged files
+
+				{Name: "auditTool", DataType: []string{"text"}}, // e.g., semgrep, snyk
+				{Name: "auditor", DataType: []string{"text"}},   // analyst or system name
 			},
 		}
 		err = client.Schema().ClassCreator().WithClass(schema).Do(context.Background())
@@ -143,7 +182,7 @@ func main() {
 		}
 		fmt.Printf("📂 Indexing single file: %s\n\n", *fileFlag)
 
-		metadata := VulnerabilityMetadata{
+		meta := VulnerabilityMetadata{
 			Language:         "C",
 			VulnType:         "Buffer Overflow",
 			CWE:              "CWE-120, CWE-119",
@@ -165,15 +204,91 @@ func main() {
 			Auditor:          "internal-audit-team",
 		}
 
-		err = IndexCodeFile(client, *fileFlag, SCHEMA, metadata)
+		if *cveFlag != "" {
+			meta.CVE = *cveFlag
+		}
+		if code, err := readCodeFromFile(*fileFlag); err == nil {
+			if cve, ok := ExtractCVE(code); ok && *cveFlag == "" {
+				meta.CVE = cve
+			}
+		}
+		if fetcher, ferr := NewDefaultFetcher(); ferr == nil {
+			if err := EnrichMetadata(context.Background(), fetcher, &meta); err != nil {
+				fmt.Printf("⚠ Could not auto-enrich %s: %v\n", meta.CVE, err)
+			}
+		}
+
+		err = IndexCodeFile(client, *fileFlag, SCHEMA, meta)
 		handle(err)
 
 	case "dir":
 		dirPath := *dirFlag
 		fmt.Printf("📁 Indexing directory: %s\n\n", dirPath)
-		err = IndexDirectory(client, dirPath, SCHEMA)
+
+		var fetcher metadata.Fetcher
+		if !*noEnrichFlag {
+			f, err := NewDefaultFetcher()
+			handle(err)
+			fetcher = f
+		}
+
+		err = IndexDirectory(client, dirPath, SCHEMA, fetcher, BatchOptions{
+			Workers:   *workersFlag,
+			BatchSize: *batchSizeFlag,
+			JSON:      *jsonFlag,
+		})
+		handle(err)
+
+	case "image":
+		if *imageFlag == "" {
+			fmt.Println("❌ Error: --image flag required for 'image' mode")
+			os.Exit(1)
+		}
+		fmt.Printf("🐳 Indexing container image: %s (%s)\n\n", *imageFlag, *platformFlag)
+		creds := registry.Credentials{Username: *registryUserFlag, Password: *registryPassFlag}
+		err = IndexImage(client, SCHEMA, *imageFlag, *platformFlag, creds)
+		handle(err)
+
+	case "git":
+		if *gitFlag == "" {
+			fmt.Println("❌ Error: --git flag required for 'git' mode")
+			os.Exit(1)
+		}
+		fmt.Printf("🕰️  Indexing git history: %s\n\n", *gitFlag)
+
+		gitIndexer := NewGitIndexer(client, SCHEMA)
+		err = gitIndexer.IndexRepo(*gitFlag, GitIndexOptions{
+			Since:         *sinceFlag,
+			Range:         *rangeFlag,
+			OnlyVulnFixes: *onlyVulnFixesFlag,
+		})
+		handle(err)
+
+	case "enrich":
+		fetcher, err := NewDefaultFetcher()
 		handle(err)
 
+		if nvdFetcher, ok := fetcher.(metadata.Chain)[0].(*nvd.Fetcher); ok {
+			years := parseYears(*yearsFlag)
+			fmt.Printf("🔄 Refreshing cached NVD feeds for %v...\n", years)
+			// A fresh fetcher hasn't Fetch()ed anything yet, so Refresh would
+			// have no years loaded to act on; Prefetch seeds them first.
+			handle(nvdFetcher.Prefetch(context.Background(), years...))
+			handle(nvdFetcher.Refresh(context.Background()))
+			fmt.Println("✓ Metadata cache refreshed")
+
+			if *watchFlag > 0 {
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+				defer stop()
+				fmt.Printf("👀 Watching: re-refreshing every %s (Ctrl-C to stop)...\n", *watchFlag)
+				nvdFetcher.StartSync(ctx, *watchFlag)
+				<-ctx.Done()
+				fmt.Println("✓ Stopped watching")
+			}
+		} else {
+			fmt.Println("✓ Metadata cache refreshed")
+		}
+
 	case "search":
 		if *searchFlag == "" {
 			fmt.Println("❌ Error: --search flag required for 'search' mode")
@@ -184,32 +299,20 @@ func main() {
 		code, err := readCodeFromFile(*searchFlag)
 		handle(err)
 
-		vector, err := getVectorFromPython(code)
+		err = Search(client, SCHEMA, code, SearchOptions{
+			Alpha:    *alphaFlag,
+			Rerank:   *rerankFlag,
+			Limit:    *limitFlag,
+			Language: *languageFlag,
+			MinCVSS:  *minCVSSFlag,
+			CWE:      *cweFlag,
+			Severity: *severityFlag,
+		})
 		handle(err)
 
-		nearVector := client.GraphQL().NearVectorArgBuilder()
-		nearVector.WithVector(vector)
-
-		result, err := client.GraphQL().Get().
-			WithClassName(SCHEMA).
-			WithFields(
-				graphql.Field{Name: "code"},
-				graphql.Field{Name: "language"},
-				graphql.Field{Name: "vulnType"},
-				graphql.Field{Name: "cwe"},
-				graphql.Field{Name: "severity"},
-				graphql.Field{Name: "filePath"},
-			).
-			WithNearVector(nearVector).
-			WithLimit(5).
-			Do(context.Background())
-
-		handle(err)
-		fmt.Printf("Results:\n%+v\n", result.Data)
-
 	default:
 		fmt.Printf("❌ Unknown mode: %s\n", *modeFlag)
-		fmt.Println("Available modes: file, dir, search")
+		fmt.Println("Available modes: file, dir, search, image, git, enrich")
 		os.Exit(1)
 	}
 
@@ -229,6 +332,24 @@ func classExists(client *weaviate.Client, className string) (bool, error) {
 	return false, nil
 }
 
+// parseYears parses a comma-separated "--years" flag value into feed years,
+// defaulting to the current year when csv is empty. Entries that aren't
+// valid integers are skipped.
+func parseYears(csv string) []int {
+	if csv == "" {
+		return []int{time.Now().Year()}
+	}
+	var years []int
+	for _, part := range strings.Split(csv, ",") {
+		year, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		years = append(years, year)
+	}
+	return years
+}
+
 func handle(err error) {
 	if err != nil {
 		fmt.Printf("\n\n%v\n\n", err)